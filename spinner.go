@@ -6,22 +6,47 @@
 //
 // If provided *os.File (usually os.Stdout or os.Sterr) is not attached to a
 // terminal, spinner outputs nothing, that makes it safe to redirect program
-// output to files, pipes, etc.
+// output to files, pipes, etc. — unless the SPINNER_HEARTBEAT environment
+// variable is set to "1" (or WithHeartbeat is used), in which case it falls
+// back to periodic "text... [mm:ss]" heartbeat lines, useful under CI
+// systems that strip carriage returns but kill jobs on silent stdout.
 //
 // Spinner can either be used manually, by first creating it with New function,
 // then periodically calling Spin() method on it to refresh output and finally
-// finishing with Clear() method call to clean output; or package-level Spin
-// shortcut function can be used to launch background goroutine that handles
-// output refresh.
+// finishing with Clear() method call to clean output; or Start/Stop methods,
+// or the package-level Spin shortcut function, can be used to launch a
+// background goroutine that handles output refresh instead.
 package spinner
 
 import (
+	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
+	"unicode/utf8"
 
 	"golang.org/x/term"
 )
 
+// heartbeatEnvVar, when set to "1", makes a Spinner attached to a non-terminal
+// *os.File fall back to emitting periodic heartbeat lines instead of staying
+// silent; see WithHeartbeat for the explicit, non-env-var equivalent.
+const heartbeatEnvVar = "SPINNER_HEARTBEAT"
+
+// heartbeatMinInterval bounds how often heartbeat lines are emitted,
+// regardless of a shorter Style.Interval meant for a real terminal's spin
+// rate — CI log collectors don't need, and often throttle, 10Hz output.
+const heartbeatMinInterval = time.Second
+
+const (
+	ansiHideCursor = "\x1b[?25l"
+	ansiShowCursor = "\x1b[?25h"
+	ansiEraseLine  = "\x1b[2K\r"
+)
+
 // Spin is a shortcut function which creates new Spinner, launches background
 // goroutine that periodically calls spinner's Spin method, and returns
 // function that stops that background goroutine and clears spinner output.
@@ -33,89 +58,453 @@ import (
 //      // logic here
 //  }
 //
+// opts, if given, configure the spinner's Style the same way NewWithOptions
+// does; the ticker driving the background goroutine is derived from that
+// Style's Interval rather than a fixed rate.
+//
 // It is expected that nothing else is writing to underlying *os.File until
 // stop function returns.
-func Spin(f *os.File, text string) (stop func()) {
-	s := New(f, text)
+func Spin(f *os.File, text string, opts ...Option) (stop func()) {
+	s := NewWithOptions(f, text, opts...)
 	if s.f == nil {
 		return func() {}
 	}
-	done := make(chan struct{})
-	bgDone := make(chan struct{})
-	ticker := time.NewTicker(time.Second / 10)
+	s.Start()
+	return s.Stop
+}
+
+// Frames is a sequence of "spinning" glyphs a Spinner cycles through. Each
+// element may be a single rune or a short multi-rune string (e.g. a braille
+// dot or an emoji), so frame sets are not limited to plain ASCII.
+type Frames []string
+
+// Style describes how a Spinner renders: which Frames it cycles through, how
+// often it advances to the next frame, and an optional ANSI color sequence
+// wrapped around each frame (e.g. ColorCyan). Color is left empty for no
+// color.
+type Style struct {
+	Frames   Frames
+	Interval time.Duration
+	Color    string
+
+	// Heartbeat forces the CI-friendly heartbeat fallback (see WithHeartbeat)
+	// even when SPINNER_HEARTBEAT isn't set in the environment. It has no
+	// effect when the underlying file is a terminal.
+	Heartbeat bool
+}
+
+// Preset styles, analogous to the frame sets bundled with other spinner
+// libraries. StyleASCII is used when no style is configured.
+var (
+	StyleASCII  = Style{Frames: Frames{"|", "/", "-", "\\"}, Interval: time.Second / 10}
+	StyleDots   = Style{Frames: Frames{"⣾", "⣽", "⣻", "⢿", "⡿", "⣟", "⣯", "⣷"}, Interval: time.Second / 10}
+	StyleBox    = Style{Frames: Frames{"▖", "▘", "▝", "▗"}, Interval: time.Second / 6}
+	StyleArrows = Style{Frames: Frames{"←", "↖", "↑", "↗", "→", "↘", "↓", "↙"}, Interval: time.Second / 8}
+	StyleMoon   = Style{Frames: Frames{"🌑", "🌒", "🌓", "🌔", "🌕", "🌖", "🌗", "🌘"}, Interval: time.Second / 4}
+	StyleBar    = Style{Frames: Frames{"▁", "▂", "▃", "▄", "▅", "▆", "▇", "█", "▇", "▆", "▅", "▄", "▃", "▂"}, Interval: time.Second / 10}
+)
+
+// Common ANSI color codes for use with Style.Color or WithColor.
+const (
+	ColorRed     = "\x1b[31m"
+	ColorGreen   = "\x1b[32m"
+	ColorYellow  = "\x1b[33m"
+	ColorBlue    = "\x1b[34m"
+	ColorMagenta = "\x1b[35m"
+	ColorCyan    = "\x1b[36m"
+
+	ansiReset = "\x1b[0m"
+)
+
+// Option configures a Spinner constructed with NewWithOptions or Spin.
+type Option func(*Style)
+
+// WithStyle selects the complete Style — frames, interval and color — used
+// by the spinner. Applying WithStyle overrides any earlier options.
+func WithStyle(st Style) Option {
+	return func(dst *Style) { *dst = st }
+}
+
+// WithFrames overrides the frame set of the spinner's Style, letting callers
+// register their own frames instead of using one of the presets.
+func WithFrames(f Frames) Option {
+	return func(dst *Style) { dst.Frames = f }
+}
+
+// WithInterval overrides the refresh interval of the spinner's Style.
+func WithInterval(d time.Duration) Option {
+	return func(dst *Style) { dst.Interval = d }
+}
+
+// WithColor wraps each rendered frame in the given ANSI color escape
+// sequence (see the Color* constants).
+func WithColor(ansiCode string) Option {
+	return func(dst *Style) { dst.Color = ansiCode }
+}
+
+// WithHeartbeat forces the CI-friendly heartbeat fallback on for a spinner
+// attached to a non-terminal file, equivalent to setting SPINNER_HEARTBEAT=1
+// in the environment. It has no effect when the underlying file is a
+// terminal.
+func WithHeartbeat() Option {
+	return func(dst *Style) { dst.Heartbeat = true }
+}
+
+// Spinner implements terminal spinner attached to *os.File which usually
+// either stdout or stderr. Both zero and nil values are valid and are no-op.
+// If spinner created on an *os.File that is not attached to the terminal,
+// spinner's methods do nothing by default, unless the heartbeat fallback
+// applies — see WithHeartbeat.
+//
+// All of Spinner's methods take an internal lock and are safe to call
+// concurrently. In particular, Write (and Printf/Println), UpdateText and
+// SetSuffix may be called from any goroutine while Start's background
+// goroutine is driving Spin, to interleave plain log output or update the
+// displayed text and progress suffix. Spinner still expects to have
+// exclusive access to the underlying *os.File — that nothing outside of
+// these methods writes to it while the spinner is in use.
+type Spinner struct {
+	f        *os.File
+	mu       sync.Mutex
+	prefix   string // TTY mode: text followed by a single space
+	frames   Frames
+	interval time.Duration
+	colorOn  string
+	colorOff string
+	n        int
+	suffix   func() string // optional, called on each render; see SetSuffix
+
+	heartbeat bool      // CI fallback mode: emit a line per tick instead of spinning
+	label     string    // heartbeat mode: raw text, no trailing space
+	start     time.Time // heartbeat mode: used for the elapsed-time suffix
+
+	lastFrame string        // frame most recently drawn, used to repaint after Write
+	stop      chan struct{} // non-nil while a Start goroutine is running
+	done      chan struct{}
+	sigStop   func() // non-nil while the cursor-restoring signal handler is installed
+}
+
+// Spin redraws output if underlying *os.File is attached to a terminal, or
+// emits the next heartbeat line in heartbeat mode.
+func (s *Spinner) Spin() {
+	if s == nil || s.f == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.heartbeat {
+		s.drawHeartbeat()
+		return
+	}
+	frame := s.frames[s.n]
+	s.n = (s.n + 1) % len(s.frames)
+	s.draw(frame)
+}
+
+// Start launches a background goroutine that periodically calls Spin, so
+// callers don't have to drive the ticker themselves. Stop ends it and clears
+// the spinner's output. Start is a no-op if the spinner is a no-op, or is
+// already running.
+func (s *Spinner) Start() {
+	if s == nil || s.f == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.stop != nil {
+		s.mu.Unlock()
+		return
+	}
+	stop, done := make(chan struct{}), make(chan struct{})
+	s.stop, s.done = stop, done
+	s.mu.Unlock()
 	go func() {
+		defer close(done)
+		ticker := time.NewTicker(s.interval)
 		defer ticker.Stop()
-		defer close(bgDone)
 		for {
 			select {
-			case <-done:
+			case <-stop:
 				return
 			case <-ticker.C:
 				s.Spin()
 			}
 		}
 	}()
-	return func() {
-		close(done)
-		<-bgDone
-		s.Clear()
+}
+
+// Stop ends the background goroutine started by Start and clears the
+// spinner's output. Stop is a no-op if Start was never called, or has
+// already been stopped.
+func (s *Spinner) Stop() {
+	if s == nil || s.f == nil {
+		return
+	}
+	s.mu.Lock()
+	stop, done := s.stop, s.done
+	s.stop, s.done = nil, nil
+	s.mu.Unlock()
+	if stop == nil {
+		return
 	}
+	close(stop)
+	<-done
+	s.Clear()
 }
 
-// Spinner implements terminal spinner attached to *os.File which usually
-// either stdout or stderr. Both zero and nil values are valid and are no-op.
-// If spinner created on an *os.File that is not attached to the terminal,
-// spinner's methods do nothing.
-//
-// Its methods are NOT thread safe, and it expects to have exclusive access to
-// underlying *os.File — that nothing is writing to it while Spinner's methods
-// are in use.
-type Spinner struct {
-	f    *os.File
-	text []byte
-	n    int
+// enableCursorControl hides the terminal cursor and installs the signal
+// handler that restores it. It's called once by NewWithOptions for every
+// terminal-attached, non-heartbeat Spinner, so the cursor is protected for
+// the spinner's whole lifetime — whether it's driven by Start/Stop or by a
+// caller-driven Spin/Clear loop (as example/main.go's manual mode does) —
+// and Clear (called by both paths) is what tears it back down.
+func (s *Spinner) enableCursorControl() {
+	s.f.WriteString(ansiHideCursor)
+	s.sigStop = s.installSignalHandler()
 }
 
-// Spin redraws output if underlying *os.File is attached to a terminal.
-func (s *Spinner) Spin() {
+// installSignalHandler arranges for the cursor to be shown again if the
+// process receives SIGINT or SIGTERM while the spinner is hiding it (e.g. the
+// user hits Ctrl-C), then re-raises the signal so the process's default
+// disposition still applies. The returned stop function removes the handler
+// once the spinner's cursor has been restored by Clear.
+func (s *Spinner) installSignalHandler() (stop func()) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
+	quit := make(chan struct{})
+	go func() {
+		select {
+		case sig := <-ch:
+			s.mu.Lock()
+			s.f.WriteString(ansiShowCursor)
+			s.mu.Unlock()
+			signal.Stop(ch)
+			if p, err := os.FindProcess(os.Getpid()); err == nil {
+				p.Signal(sig)
+			}
+		case <-quit:
+			signal.Stop(ch)
+		}
+	}()
+	return func() { close(quit) }
+}
+
+// UpdateText replaces the spinner's label. Safe to call from any goroutine,
+// including while a Start goroutine is running.
+func (s *Spinner) UpdateText(text string) {
 	if s == nil || s.f == nil {
 		return
 	}
-	const chars = `|/-\`
-	s.n = (s.n + 1) % len(chars)
-	s.text[len(s.text)-2] = chars[s.n]
-	s.f.Write(s.text)
+	s.mu.Lock()
+	if s.heartbeat {
+		s.label = text
+	} else {
+		s.prefix = text + " "
+	}
+	s.mu.Unlock()
+}
+
+// SetSuffix installs fn as a hook the render loop calls on each tick; its
+// return value is appended after the spin frame, e.g. for "13/26 processed"
+// style counters. The suffix is truncated so that the line, counted in
+// runes, fits the terminal width and a long dynamic suffix doesn't wrap; the
+// text and frame themselves are never truncated. Safe to call from any
+// goroutine; pass nil to remove the suffix.
+func (s *Spinner) SetSuffix(fn func() string) {
+	if s == nil || s.f == nil {
+		return
+	}
+	s.mu.Lock()
+	s.suffix = fn
+	s.mu.Unlock()
+}
+
+// draw writes frame, plus the optional suffix, to the underlying file and
+// records the frame drawn so a later repaint (after Write) shows the same
+// one. Callers must hold s.mu.
+func (s *Spinner) draw(frame string) {
+	out := s.prefix
+	if s.colorOn != "" {
+		out += s.colorOn + frame + s.colorOff
+	} else {
+		out += frame
+	}
+	if s.suffix != nil {
+		suf := " " + s.suffix()
+		if w, _, err := term.GetSize(int(s.f.Fd())); err == nil && w > 0 {
+			visible := utf8.RuneCountInString(s.prefix) + utf8.RuneCountInString(frame)
+			suf = truncate(suf, w-visible)
+		}
+		out += suf
+	}
+	s.lastFrame = frame
+	s.f.WriteString(out + "\r")
+}
+
+// drawHeartbeat writes one heartbeat line, e.g. "working... [00:12]\n".
+// Callers must hold s.mu.
+func (s *Spinner) drawHeartbeat() {
+	d := time.Since(s.start)
+	mm, ss := int(d/time.Minute), int(d/time.Second)%60
+	fmt.Fprintf(s.f, "%s [%02d:%02d]\n", s.label, mm, ss)
+}
+
+// truncate trims s to at most n runes, replacing the cut tail with an
+// ellipsis. It returns s unchanged if it already fits, and "" if n <= 0.
+func truncate(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	if n == 1 {
+		return "…"
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// erase clears the terminal line the spinner last drew on, using the ANSI
+// erase-in-line sequence so the cost is O(1) regardless of what was drawn.
+// Callers must hold s.mu and must not call it in heartbeat mode, where there
+// is no redrawn line to erase.
+func (s *Spinner) erase() {
+	s.f.WriteString(ansiEraseLine)
 }
 
 // Clear redraws output with spaces, clearing previous output if underlying
-// *os.File is attached to a terminal.
+// *os.File is attached to a terminal, shows the cursor again, and removes
+// the signal handler installed by enableCursorControl. It is a no-op in
+// heartbeat mode, where prior heartbeat lines are already newline-terminated
+// and nothing needs clearing.
 func (s *Spinner) Clear() {
 	if s == nil || s.f == nil {
 		return
 	}
-	b := make([]byte, len(s.text))
-	for i := range b {
-		b[i] = ' '
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.heartbeat {
+		return
+	}
+	s.erase()
+	s.f.WriteString(ansiShowCursor)
+	if s.sigStop != nil {
+		s.sigStop()
+		s.sigStop = nil
+	}
+}
+
+// Write implements io.Writer: it erases the currently drawn spin line, writes
+// p unchanged, then repaints the spinner below it, so log output interleaves
+// with an active spinner instead of leaving stray blank lines. It is safe to
+// call from any goroutine, including one different from the one driving
+// Spin. If the spinner is a no-op (not attached to a terminal and heartbeat
+// mode doesn't apply), p is discarded, mirroring Spin and Clear. In
+// heartbeat mode, p is written through unchanged, since there's no spin line
+// to protect.
+func (s *Spinner) Write(p []byte) (int, error) {
+	if s == nil || s.f == nil {
+		return len(p), nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.heartbeat {
+		return s.f.Write(p)
 	}
-	b[len(b)-1] = '\r'
-	s.f.Write(b)
+	s.erase()
+	n, err := s.f.Write(p)
+	if s.lastFrame != "" {
+		s.draw(s.lastFrame)
+	}
+	return n, err
+}
+
+// Printf formats according to a format specifier and emits the result as a
+// line above the spinner, the same way Write does. A trailing newline is
+// added if the formatted message doesn't already end with one.
+func (s *Spinner) Printf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if len(msg) == 0 || msg[len(msg)-1] != '\n' {
+		msg += "\n"
+	}
+	s.Write([]byte(msg))
+}
+
+// Println formats args the way fmt.Println does and emits the result as a
+// line above the spinner, the same way Write does.
+func (s *Spinner) Println(args ...any) {
+	s.Write([]byte(fmt.Sprintln(args...)))
+}
+
+// Wrap returns an io.Writer that routes writes through s when s is active
+// (attached to a terminal, or in heartbeat mode, where s writes them to its
+// own file unchanged), so they interleave cleanly with the active spinner,
+// and falls back to writing to w directly when s is nil or a no-op. This
+// lets s's destination double as a log.SetOutput or zerolog.ConsoleWriter{Out: ...} target
+// without producing an extra blank line between log lines while the spinner
+// is running.
+func Wrap(w io.Writer, s *Spinner) io.Writer {
+	return wrapWriter{w: w, s: s}
+}
+
+type wrapWriter struct {
+	w io.Writer
+	s *Spinner
+}
+
+func (wr wrapWriter) Write(p []byte) (int, error) {
+	if wr.s != nil && wr.s.f != nil {
+		return wr.s.Write(p)
+	}
+	return wr.w.Write(p)
 }
 
 // New returns new Spinner attached to f which usually either os.Stdout or
 // os.Stderr. If f is attached to a terminal, retrurned spinner would output
-// text followed by space and "spinning" character on each Spin call.
+// text followed by space and "spinning" character on each Spin call, using
+// the default ASCII style. Use NewWithOptions to pick a different Style.
 //
 // *os.File provided must not be nil.
 func New(f *os.File, text string) *Spinner {
+	return NewWithOptions(f, text)
+}
+
+// NewWithOptions is like New but accepts Options that configure the
+// spinner's Style, such as its frame set, refresh interval, color, and the
+// heartbeat fallback for when f isn't a terminal (see WithHeartbeat).
+func NewWithOptions(f *os.File, text string, opts ...Option) *Spinner {
+	st := StyleASCII
+	for _, opt := range opts {
+		opt(&st)
+	}
+	if len(st.Frames) == 0 {
+		st.Frames = StyleASCII.Frames
+	}
+	if st.Interval <= 0 {
+		st.Interval = StyleASCII.Interval
+	}
 	if !term.IsTerminal(int(f.Fd())) {
-		return &Spinner{}
+		if !st.Heartbeat && os.Getenv(heartbeatEnvVar) != "1" {
+			return &Spinner{}
+		}
+		interval := st.Interval
+		if interval < heartbeatMinInterval {
+			interval = heartbeatMinInterval
+		}
+		return &Spinner{f: f, label: text, heartbeat: true, interval: interval, start: time.Now()}
 	}
-
-	// b is "text"+" "+spinchar+"\r"
-	b := make([]byte, len(text)+3)
-	copy(b, []byte(text))
-	b[len(b)-3] = ' '
-	// b[len(b)-2] is replaced on each Spin() call
-	b[len(b)-1] = '\r'
-	return &Spinner{f: f, text: b}
+	colorOn, colorOff := "", ""
+	if st.Color != "" {
+		colorOn, colorOff = st.Color, ansiReset
+	}
+	s := &Spinner{
+		f:        f,
+		prefix:   text + " ",
+		frames:   st.Frames,
+		interval: st.Interval,
+		colorOn:  colorOn,
+		colorOff: colorOff,
+	}
+	s.enableCursorControl()
+	return s
 }
@@ -0,0 +1,504 @@
+package spinner
+
+import (
+	"io"
+	"os"
+	"testing"
+	"time"
+)
+
+// newTestSpinner builds a live Spinner directly, bypassing NewWithOptions'
+// terminal check, so tests can drive the render path against a plain
+// os.Pipe (which os.Fd()-based term.IsTerminal never reports as a tty).
+func newTestSpinner(w *os.File, text string, frames Frames, color string) *Spinner {
+	colorOn, colorOff := "", ""
+	if color != "" {
+		colorOn, colorOff = color, ansiReset
+	}
+	return &Spinner{
+		f:        w,
+		prefix:   text + " ",
+		frames:   frames,
+		interval: time.Millisecond,
+		colorOn:  colorOn,
+		colorOff: colorOff,
+	}
+}
+
+func readAvailable(t *testing.T, r *os.File) []byte {
+	t.Helper()
+	if err := r.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("SetReadDeadline: %v", err)
+	}
+	buf := make([]byte, 4096)
+	n, err := r.Read(buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return buf[:n]
+}
+
+func TestSpinMultiRuneFrame(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	s := newTestSpinner(w, "working...", Frames{"⣾", "⣽"}, "")
+
+	s.Spin()
+	if got, want := string(readAvailable(t, r)), "working... ⣾\r"; got != want {
+		t.Fatalf("Spin() wrote %q, want %q", got, want)
+	}
+	s.Spin()
+	if got, want := string(readAvailable(t, r)), "working... ⣽\r"; got != want {
+		t.Fatalf("second Spin() wrote %q, want %q", got, want)
+	}
+	s.Spin()
+	if got, want := string(readAvailable(t, r)), "working... ⣾\r"; got != want {
+		t.Fatalf("third Spin() (wrapped) wrote %q, want %q", got, want)
+	}
+}
+
+func TestSpinColor(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	s := newTestSpinner(w, "x", Frames{"|"}, ColorCyan)
+
+	s.Spin()
+	want := "x " + ColorCyan + "|" + ansiReset + "\r"
+	if got := string(readAvailable(t, r)); got != want {
+		t.Fatalf("Spin() wrote %q, want %q", got, want)
+	}
+}
+
+func TestWithOptionsSetFields(t *testing.T) {
+	var st Style
+	WithFrames(Frames{"a", "b"})(&st)
+	if len(st.Frames) != 2 || st.Frames[0] != "a" {
+		t.Fatalf("WithFrames didn't set Frames: %+v", st)
+	}
+	WithInterval(time.Second)(&st)
+	if st.Interval != time.Second {
+		t.Fatalf("WithInterval didn't set Interval: %+v", st)
+	}
+	WithColor(ColorRed)(&st)
+	if st.Color != ColorRed {
+		t.Fatalf("WithColor didn't set Color: %+v", st)
+	}
+	WithStyle(StyleDots)(&st)
+	if st.Interval != StyleDots.Interval || st.Color != StyleDots.Color || len(st.Frames) != len(StyleDots.Frames) {
+		t.Fatalf("WithStyle didn't replace the whole Style: %+v", st)
+	}
+}
+
+func TestWriteErasesAndRepaints(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	s := newTestSpinner(w, "work", Frames{"|", "/"}, "")
+
+	s.Spin()
+	readAvailable(t, r) // drain the initial draw, leaving lastFrame == "|"
+
+	n, err := s.Write([]byte("log line\n"))
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if n != len("log line\n") {
+		t.Fatalf("Write returned n=%d, want %d", n, len("log line\n"))
+	}
+	want := ansiEraseLine + "log line\n" + "work |\r"
+	if got := string(readAvailable(t, r)); got != want {
+		t.Fatalf("Write() wrote %q, want %q", got, want)
+	}
+}
+
+func TestWriteBeforeAnySpinDoesNotRepaint(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	s := newTestSpinner(w, "work", Frames{"|"}, "")
+
+	if _, err := s.Write([]byte("log line\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	want := ansiEraseLine + "log line\n"
+	if got := string(readAvailable(t, r)); got != want {
+		t.Fatalf("Write() wrote %q, want %q", got, want)
+	}
+}
+
+func TestPrintfAddsMissingNewline(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	s := newTestSpinner(w, "work", Frames{"|"}, "")
+
+	s.Printf("%d/%d processed", 3, 10)
+	want := ansiEraseLine + "3/10 processed\n"
+	if got := string(readAvailable(t, r)); got != want {
+		t.Fatalf("Printf() wrote %q, want %q", got, want)
+	}
+}
+
+func TestPrintlnJoinsArgsWithSpaces(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	s := newTestSpinner(w, "work", Frames{"|"}, "")
+
+	s.Println("a", "b", 3)
+	want := ansiEraseLine + "a b 3\n"
+	if got := string(readAvailable(t, r)); got != want {
+		t.Fatalf("Println() wrote %q, want %q", got, want)
+	}
+}
+
+func TestWrapFallsBackWhenSpinnerIsNoOp(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	ww := Wrap(w, &Spinner{}) // zero-value Spinner: f is nil, so it's a no-op
+	if _, err := ww.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if got, want := string(readAvailable(t, r)), "hello\n"; got != want {
+		t.Fatalf("Wrap fallback wrote %q, want %q", got, want)
+	}
+}
+
+func TestWrapRoutesThroughActiveSpinner(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	s := newTestSpinner(w, "work", Frames{"|"}, "")
+
+	ww := Wrap(w, s)
+	if _, err := ww.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	want := ansiEraseLine + "hello\n"
+	if got := string(readAvailable(t, r)); got != want {
+		t.Fatalf("Wrap wrote %q, want %q", got, want)
+	}
+}
+
+func TestStartStopDrivesAndClears(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	s := newTestSpinner(w, "work", Frames{"|", "/"}, "")
+	s.interval = 5 * time.Millisecond
+
+	s.Start()
+	s.Start() // second Start before Stop must be a no-op, not a second goroutine
+	time.Sleep(30 * time.Millisecond)
+	s.Stop()
+	s.Stop() // second Stop must be a no-op too
+
+	got := string(readAvailable(t, r))
+	if len(got) == 0 {
+		t.Fatal("Start didn't drive any Spin ticks")
+	}
+	if !bytesHasSuffix(got, ansiEraseLine+ansiShowCursor) {
+		t.Fatalf("Stop() output %q doesn't end with Clear's erase+show-cursor sequence", got)
+	}
+}
+
+func bytesHasSuffix(s, suffix string) bool {
+	return len(s) >= len(suffix) && s[len(s)-len(suffix):] == suffix
+}
+
+func TestUpdateText(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	s := newTestSpinner(w, "before", Frames{"|"}, "")
+
+	s.UpdateText("after")
+	s.Spin()
+	want := "after |\r"
+	if got := string(readAvailable(t, r)); got != want {
+		t.Fatalf("Spin() after UpdateText wrote %q, want %q", got, want)
+	}
+}
+
+func TestSetSuffixAppendsToFrame(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	s := newTestSpinner(w, "work", Frames{"|"}, "")
+
+	s.SetSuffix(func() string { return "5/10" })
+	s.Spin()
+	// w is a pipe, not a terminal, so term.GetSize fails and the suffix isn't
+	// truncated — see TestTruncate for the truncation logic itself.
+	want := "work | 5/10\r"
+	if got := string(readAvailable(t, r)); got != want {
+		t.Fatalf("Spin() with suffix wrote %q, want %q", got, want)
+	}
+
+	s.SetSuffix(nil)
+	s.Spin()
+	want = "work |\r"
+	if got := string(readAvailable(t, r)); got != want {
+		t.Fatalf("Spin() after clearing suffix wrote %q, want %q", got, want)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	cases := []struct {
+		in   string
+		n    int
+		want string
+	}{
+		{"", 5, ""},
+		{"hello", 0, ""},
+		{"hello", 5, "hello"},
+		{"hello", 10, "hello"},
+		{"hello world", 5, "hell…"},
+		{"hello", 1, "…"},
+	}
+	for _, c := range cases {
+		if got := truncate(c.in, c.n); got != c.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", c.in, c.n, got, c.want)
+		}
+	}
+}
+
+func TestHeartbeatLineFormat(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	s := &Spinner{
+		f:         w,
+		label:     "working...",
+		heartbeat: true,
+		interval:  time.Millisecond,
+		start:     time.Now().Add(-72 * time.Second),
+	}
+
+	s.Spin()
+	want := "working... [01:12]\n"
+	if got := string(readAvailable(t, r)); got != want {
+		t.Fatalf("heartbeat Spin() wrote %q, want %q", got, want)
+	}
+}
+
+func TestHeartbeatWriteAndClearPassThrough(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	s := &Spinner{f: w, label: "x", heartbeat: true, interval: time.Millisecond, start: time.Now()}
+
+	if _, err := s.Write([]byte("log line\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if got, want := string(readAvailable(t, r)), "log line\n"; got != want {
+		t.Fatalf("heartbeat Write() wrote %q, want %q (no erase/repaint in heartbeat mode)", got, want)
+	}
+
+	s.Clear() // must be a no-op: nothing drawn that needs erasing
+	w.Close()
+	buf := make([]byte, 64)
+	n, err := r.Read(buf)
+	if n != 0 {
+		t.Fatalf("heartbeat Clear() wrote %q, want nothing", buf[:n])
+	}
+	if err != io.EOF {
+		t.Fatalf("Read after close: got err %v, want io.EOF", err)
+	}
+}
+
+func TestClearErasesRestoresCursorAndRemovesSignalHandler(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	s := newTestSpinner(w, "work", Frames{"|"}, "")
+	s.enableCursorControl()
+	defer func() {
+		if s.sigStop != nil {
+			s.sigStop()
+		}
+	}()
+
+	s.Spin()
+	readAvailable(t, r) // drain the hide-cursor sequence plus the initial draw
+
+	s.Clear()
+	want := ansiEraseLine + ansiShowCursor
+	if got := string(readAvailable(t, r)); got != want {
+		t.Fatalf("Clear() wrote %q, want %q", got, want)
+	}
+	if s.sigStop != nil {
+		t.Fatal("Clear() left the cursor-restoring signal handler installed")
+	}
+}
+
+func TestManualSpinClearLoopProtectsCursor(t *testing.T) {
+	// Regression test: example/main.go's manual New-then-loop-Spin-then-Clear
+	// usage never calls Start, so the cursor hide/restore pair and its
+	// signal handler must be installed by NewWithOptions itself, not Start.
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	s := newTestSpinner(w, "work", Frames{"|"}, "")
+	s.enableCursorControl() // what NewWithOptions does for a terminal-attached Spinner
+
+	if s.sigStop == nil {
+		t.Fatal("enableCursorControl didn't install the cursor-restoring signal handler")
+	}
+	readAvailable(t, r) // drain ansiHideCursor
+
+	s.Spin()
+	s.Clear()
+	if s.sigStop != nil {
+		t.Fatal("Clear() didn't remove the signal handler after a manual Spin/Clear loop")
+	}
+}
+
+func TestNewWithOptionsNoOpOnNonTerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	s := NewWithOptions(w, "working...")
+	if s.f != nil {
+		t.Fatal("NewWithOptions on a pipe without WithHeartbeat or SPINNER_HEARTBEAT should return a no-op Spinner")
+	}
+	s.Spin() // must not panic or write anything on a no-op Spinner
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 64)
+	if n, err := r.Read(buf); n != 0 || err != io.EOF {
+		t.Fatalf("no-op Spinner wrote %q (err %v), want nothing", buf[:n], err)
+	}
+}
+
+func TestNewWithOptionsHeartbeatViaOption(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	s := NewWithOptions(w, "working...", WithHeartbeat())
+	if !s.heartbeat {
+		t.Fatal("WithHeartbeat() didn't select the heartbeat fallback on a non-terminal *os.File")
+	}
+	s.Spin()
+	if got := string(readAvailable(t, r)); got == "" {
+		t.Fatal("heartbeat Spinner wrote nothing")
+	}
+}
+
+func TestNewWithOptionsHeartbeatViaEnvVar(t *testing.T) {
+	t.Setenv(heartbeatEnvVar, "1")
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	s := NewWithOptions(w, "working...")
+	if !s.heartbeat {
+		t.Fatal("SPINNER_HEARTBEAT=1 didn't select the heartbeat fallback on a non-terminal *os.File")
+	}
+	s.Spin()
+	if got := string(readAvailable(t, r)); got == "" {
+		t.Fatal("heartbeat Spinner wrote nothing")
+	}
+}
+
+func TestNewWithOptionsHeartbeatClampsInterval(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	s := NewWithOptions(w, "working...", WithHeartbeat(), WithInterval(time.Millisecond))
+	if s.interval < heartbeatMinInterval {
+		t.Fatalf("heartbeat interval = %v, want clamped to at least %v", s.interval, heartbeatMinInterval)
+	}
+}
+
+func TestPackageSpinDrivesAndStops(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	t.Setenv(heartbeatEnvVar, "1")
+
+	// Heartbeat mode clamps its interval to heartbeatMinInterval, so the
+	// ticker needs at least that long to fire once before stop() is called.
+	stop := Spin(w, "working...")
+	time.Sleep(heartbeatMinInterval + 100*time.Millisecond)
+	stop()
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if len(got) == 0 {
+		t.Fatal("package-level Spin() didn't drive any output before stop() returned")
+	}
+}